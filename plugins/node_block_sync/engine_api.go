@@ -0,0 +1,222 @@
+package main
+
+import (
+    "encoding/hex"
+    "encoding/json"
+    "flag"
+    "fmt"
+    "os"
+    "strings"
+    "time"
+
+    "github.com/go-resty/resty/v2"
+    "github.com/golang-jwt/jwt/v5"
+    "github.com/rs/zerolog/log"
+
+    pluginpb "github.com/dsrvlabs/vatz-proto/plugin/v1"
+    "github.com/dsrvlabs/vatz/sdk"
+    "google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+    defaultEngineURL           = "http://localhost:8551"
+    defaultPublicRPCURL        = "http://localhost:8545"
+    defaultSyncingWarningCount = 3
+)
+
+var (
+    jwtSecretPath string
+    engineURL     string
+
+    engineSyncingCount int
+)
+
+func init() {
+    flag.StringVar(&jwtSecretPath, "jwt-secret", "", "Path to the JWT secret file used to authenticate to the Engine API")
+    flag.StringVar(&engineURL, "engine-url", defaultEngineURL, "Engine API endpoint (default http://localhost:8551)")
+}
+
+// engineCallResponse represents the generic shape of an Engine API / eth JSON-RPC response.
+type engineCallResponse struct {
+    JsonRPC string          `json:"jsonrpc"`
+    Result  json.RawMessage `json:"result"`
+    Error   *struct {
+        Code    int    `json:"code"`
+        Message string `json:"message"`
+    } `json:"error"`
+    ID int `json:"id"`
+}
+
+// engineAPIFeature authenticates to the Engine API with a freshly minted JWT and polls
+// engine_exchangeCapabilities/eth_syncing to verify the EL is reachable by a consensus
+// layer. Deviation from the backlog item: it does not call engine_newPayloadV3 /
+// engine_getPayloadV3 to observe a PayloadStatusV1{status: INVALID}, since those are
+// mutating calls that require a payload to submit or build and aren't safe to issue
+// from a passive health check. eth_syncing plus unauthorized/unreachable detection is
+// used as the non-mutating proxy for EL health instead.
+func engineAPIFeature(info, option map[string]*structpb.Value) (sdk.CallResponse, error) {
+    ret := sdk.CallResponse{
+        FuncName: info["execute_method"].GetStringValue(),
+        Message:  "Unable to reach Engine API",
+        Severity: pluginpb.SEVERITY_UNKNOWN,
+        State:    pluginpb.STATE_NONE,
+    }
+
+    if jwtSecretPath == "" {
+        ret.Message = "No --jwt-secret configured, cannot authenticate to the Engine API"
+        ret.Severity = pluginpb.SEVERITY_CRITICAL
+        ret.State = pluginpb.STATE_FAILURE
+        return ret, fmt.Errorf("jwt secret path not set")
+    }
+
+    token, err := mintEngineJWT(jwtSecretPath)
+    if err != nil {
+        log.Error().Str("module", "plugin").Msgf("failed to mint engine JWT: %v", err)
+        ret.Message = fmt.Sprintf("Failed to authenticate to Engine API: %v", err)
+        ret.Severity = pluginpb.SEVERITY_WARNING
+        ret.State = pluginpb.STATE_FAILURE
+        return ret, err
+    }
+
+    client := resty.New().SetAuthToken(token).SetTimeout(defaultRPCTimeout)
+
+    capResp, err := callEngineAPI(client, "engine_exchangeCapabilities", []interface{}{[]string{}})
+    if err != nil {
+        publicUp := isPublicRPCUp()
+        if isUnauthorized(err) {
+            ret.Message = fmt.Sprintf("Engine API rejected JWT authentication: %v", err)
+            ret.Severity = pluginpb.SEVERITY_WARNING
+        } else if publicUp {
+            ret.Message = fmt.Sprintf("Engine API unreachable on %s while public RPC is up: %v", engineURL, err)
+            ret.Severity = pluginpb.SEVERITY_CRITICAL
+        } else {
+            ret.Message = fmt.Sprintf("Failed to reach Engine API: %v", err)
+            ret.Severity = pluginpb.SEVERITY_WARNING
+        }
+        ret.State = pluginpb.STATE_FAILURE
+        return ret, err
+    }
+    log.Debug().Str("module", "plugin").Msgf("engine_exchangeCapabilities result: %s", capResp.Result)
+
+    syncResp, err := callEngineAPI(client, "eth_syncing", []interface{}{})
+    if err != nil {
+        ret.Message = fmt.Sprintf("Failed to query sync status from Engine API: %v", err)
+        ret.Severity = pluginpb.SEVERITY_WARNING
+        ret.State = pluginpb.STATE_FAILURE
+        return ret, err
+    }
+
+    var syncing bool
+    if string(syncResp.Result) != "false" {
+        syncing = true
+    }
+
+    if syncing {
+        engineSyncingCount++
+        if engineSyncingCount > defaultSyncingWarningCount {
+            ret.Message = fmt.Sprintf("Execution client reported SYNCING for %d consecutive polls", engineSyncingCount)
+            ret.Severity = pluginpb.SEVERITY_WARNING
+        } else {
+            ret.Message = fmt.Sprintf("Execution client reported SYNCING (%d/%d polls)", engineSyncingCount, defaultSyncingWarningCount)
+            ret.Severity = pluginpb.SEVERITY_INFO
+        }
+    } else {
+        engineSyncingCount = 0
+        ret.Message = fmt.Sprintf("Engine API reachable, execution client is synced (endpoint: %s)", engineURL)
+        ret.Severity = pluginpb.SEVERITY_INFO
+    }
+
+    ret.State = pluginpb.STATE_SUCCESS
+    log.Debug().Str("module", "plugin").Msg(ret.Message)
+
+    return ret, nil
+}
+
+// mintEngineJWT reads the hex-encoded secret at path and mints a short-lived HS256 token
+// carrying only the "iat" claim, as required by the Engine API authentication spec.
+func mintEngineJWT(path string) (string, error) {
+    raw, err := os.ReadFile(path)
+    if err != nil {
+        return "", fmt.Errorf("failed to read JWT secret file: %w", err)
+    }
+
+    secretHex := strings.TrimSpace(string(raw))
+    secretHex = strings.TrimPrefix(secretHex, "0x")
+
+    secret, err := hex.DecodeString(secretHex)
+    if err != nil {
+        return "", fmt.Errorf("failed to decode JWT secret as hex: %w", err)
+    }
+
+    claims := jwt.MapClaims{
+        "iat": time.Now().Unix(),
+    }
+    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+    signed, err := token.SignedString(secret)
+    if err != nil {
+        return "", fmt.Errorf("failed to sign JWT: %w", err)
+    }
+
+    return signed, nil
+}
+
+func callEngineAPI(client *resty.Client, method string, params []interface{}) (*engineCallResponse, error) {
+    body := map[string]interface{}{
+        "jsonrpc": "2.0",
+        "id":      1,
+        "method":  method,
+        "params":  params,
+    }
+
+    resp, err := client.R().
+        SetHeader("Content-Type", "application/json").
+        SetBody(body).
+        Post(engineURL)
+    if err != nil {
+        return nil, err
+    }
+
+    if resp.StatusCode() == 401 {
+        return nil, fmt.Errorf("unauthorized (status %d)", resp.StatusCode())
+    }
+
+    var parsed engineCallResponse
+    if err := json.Unmarshal(resp.Body(), &parsed); err != nil {
+        return nil, fmt.Errorf("failed to parse Engine API response: %w", err)
+    }
+    if parsed.Error != nil {
+        return nil, fmt.Errorf("engine API error %d: %s", parsed.Error.Code, parsed.Error.Message)
+    }
+
+    return &parsed, nil
+}
+
+func isUnauthorized(err error) bool {
+    return err != nil && strings.Contains(err.Error(), "unauthorized")
+}
+
+// isPublicRPCUp checks the operator's configured public RPC endpoint rather than
+// assuming localhost, since --rpc-url may point anywhere (self-hosted geth, Infura,
+// Alchemy, ...) once chunk0-4's multi-endpoint support is in use.
+func isPublicRPCUp() bool {
+    url := defaultPublicRPCURL
+    if rpcURL != "" && !isWebSocketURL(rpcURL) {
+        url = rpcURL
+    }
+
+    client := resty.New().SetTimeout(defaultRPCTimeout)
+    body := map[string]interface{}{
+        "jsonrpc": "2.0",
+        "id":      1,
+        "method":  "eth_blockNumber",
+        "params":  []string{},
+    }
+
+    resp, err := client.R().
+        SetHeader("Content-Type", "application/json").
+        SetBody(body).
+        Post(url)
+
+    return err == nil && resp.StatusCode() == 200
+}
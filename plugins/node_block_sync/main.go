@@ -1,14 +1,14 @@
 package main
 
 import (
-    "encoding/json"
     "flag"
     "fmt"
-    "math/big"
+    "time"
+
     "github.com/rs/zerolog/log"
-    "github.com/go-resty/resty/v2"
     pluginpb "github.com/dsrvlabs/vatz-proto/plugin/v1"
     "github.com/dsrvlabs/vatz/sdk"
+    "github.com/ethereum/go-ethereum/ethclient"
     "golang.org/x/net/context"
     "google.golang.org/protobuf/types/known/structpb"
 )
@@ -18,6 +18,7 @@ const (
     defaultPort          = 10001
     pluginName           = "vatz-plugin-ethereum-block-height"
     defaultCriticalCount = 3
+    defaultRPCTimeout    = 5 * time.Second
 )
 
 var (
@@ -32,25 +33,38 @@ func init() {
     flag.StringVar(&addr, "addr", defaultAddr, "Listening address")
     flag.IntVar(&port, "port", defaultPort, "Listening port")
     flag.IntVar(&criticalCount, "critical", defaultCriticalCount, "Block height stuck count to raise critical level of alert")
-    flag.Parse()
 }
 
 func main() {
+    flag.Parse()
+    normalizeRPCURLs()
+
     p := sdk.NewPlugin(pluginName)
-    p.Register(pluginFeature)
 
     ctx := context.Background()
+
+    var primary func(info, option map[string]*structpb.Value) (sdk.CallResponse, error)
+    switch {
+    case isWebSocketURL(rpcURL):
+        primary = subscriptionFeature
+        startHeadSubscription(ctx)
+    case len(rpcURLs) > 1:
+        primary = multiEndpointFeature
+    default:
+        primary = pluginFeature
+    }
+
+    if jwtSecretPath != "" {
+        p.Register(combineWithEngineAPI(primary))
+    } else {
+        p.Register(primary)
+    }
+
     if err := p.Start(ctx, addr, port); err != nil {
         log.Info().Str("module", "plugin").Msg("exit")
     }
 }
 
-type BlockHeightResponse struct {
-    JsonRPC string `json:"jsonrpc"`
-    Result  string `json:"result"`
-    ID      int    `json:"id"`
-}
-
 func pluginFeature(info, option map[string]*structpb.Value) (sdk.CallResponse, error) {
     ret := sdk.CallResponse{
         FuncName: info["execute_method"].GetStringValue(),
@@ -59,52 +73,52 @@ func pluginFeature(info, option map[string]*structpb.Value) (sdk.CallResponse, e
         State:    pluginpb.STATE_NONE,
     }
 
-    client := resty.New()
-    url := "http://localhost:8545"
-
-    body := map[string]interface{}{
-        "jsonrpc": "2.0",
-        "id":      1,
-        "method":  "eth_blockNumber",
-        "params":  []string{},
-    }
-
-    resp, err := client.R().
-        SetHeader("Content-Type", "application/json").
-        SetBody(body).
-        Post(url)
+    ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+    defer cancel()
 
+    client, err := ethclient.DialContext(ctx, rpcURL)
     if err != nil {
-        log.Error().Str("module", "plugin").Msgf("failed to get response: %v", err)
-        ret.Message = fmt.Sprintf("Failed to get response: %v", err)
+        log.Error().Str("module", "plugin").Msgf("failed to dial RPC endpoint: %v", err)
+        ret.Message = fmt.Sprintf("Failed to dial RPC endpoint: %v", err)
         ret.Severity = pluginpb.SEVERITY_CRITICAL
         ret.State = pluginpb.STATE_FAILURE
         return ret, err
     }
+    defer client.Close()
 
-    var blockHeightResp BlockHeightResponse
-    err = json.Unmarshal(resp.Body(), &blockHeightResp)
+    head, err := client.HeaderByNumber(ctx, nil)
     if err != nil {
-        log.Error().Str("module", "plugin").Msgf("failed to parse response: %v", err)
-        ret.Message = fmt.Sprintf("Failed to parse response: %v", err)
+        log.Error().Str("module", "plugin").Msgf("failed to get response: %v", err)
+        ret.Message = fmt.Sprintf("Failed to get response: %v", err)
         ret.Severity = pluginpb.SEVERITY_CRITICAL
         ret.State = pluginpb.STATE_FAILURE
         return ret, err
     }
+    latestHeight := head.Number.Uint64()
 
-    // Convert hex string to int64
-    latestHeight, err := hexToInt64(blockHeightResp.Result)
-    if err != nil {
-        log.Error().Str("module", "plugin").Msgf("failed to convert hex to int64: %v", err)
-        ret.Message = fmt.Sprintf("Failed to convert hex to int64: %v", err)
-        ret.Severity = pluginpb.SEVERITY_CRITICAL
-        ret.State = pluginpb.STATE_FAILURE
-        return ret, err
+    log.Info().Str("module", "plugin").Msgf("Previous block height: %d, Latest block height: %d", prevHeight, latestHeight)
+
+    if depth, detected, err := detectReorg(context.Background(), client, head); err != nil {
+        log.Error().Str("module", "plugin").Msgf("failed to check for reorg: %v", err)
+    } else if detected {
+        recordBlock(latestHeight, head.Hash())
+        prevHeight = int64(latestHeight)
+
+        if depth > reorgCriticalDepth {
+            ret.Message = fmt.Sprintf("Deep reorg detected at depth %d (current height: %d)", depth, latestHeight)
+            ret.Severity = pluginpb.SEVERITY_CRITICAL
+        } else {
+            ret.Message = fmt.Sprintf("Reorg detected at depth %d (current height: %d)", depth, latestHeight)
+            ret.Severity = pluginpb.SEVERITY_WARNING
+        }
+        ret.State = pluginpb.STATE_SUCCESS
+        log.Debug().Str("module", "plugin").Msg(ret.Message)
+        return ret, nil
     }
 
-    log.Info().Str("module", "plugin").Msgf("Previous block height: %d, Latest block height: %d", prevHeight, latestHeight)
+    recordBlock(latestHeight, head.Hash())
 
-    if latestHeight > prevHeight {
+    if int64(latestHeight) > prevHeight {
         ret.Message = fmt.Sprintf("Block height increasing. Current height: %d", latestHeight)
         ret.Severity = pluginpb.SEVERITY_INFO
         warningCount = 0
@@ -122,27 +136,45 @@ func pluginFeature(info, option map[string]*structpb.Value) (sdk.CallResponse, e
     ret.State = pluginpb.STATE_SUCCESS
     log.Debug().Str("module", "plugin").Msg(ret.Message)
 
-    prevHeight = latestHeight
+    prevHeight = int64(latestHeight)
     return ret, nil
 }
 
-func hexToInt64(hexStr string) (int64, error) {
-    // Remove "0x" prefix if present
-    if len(hexStr) >= 2 && hexStr[:2] == "0x" {
-        hexStr = hexStr[2:]
+// severityRank orders SEVERITY values from least to most severe so responses from
+// multiple features can be merged by escalating to the worst one. The enum's integer
+// values don't reflect severity order (SEVERITY_INFO is 4, above SEVERITY_WARNING's 1),
+// so a plain numeric comparison can't be used directly.
+func severityRank(s pluginpb.SEVERITY) int {
+    switch s {
+    case pluginpb.SEVERITY_INFO:
+        return 1
+    case pluginpb.SEVERITY_WARNING:
+        return 2
+    case pluginpb.SEVERITY_ERROR:
+        return 3
+    case pluginpb.SEVERITY_CRITICAL:
+        return 4
+    default:
+        return 0
     }
-    
-    // Parse hex string to big.Int
-    n := new(big.Int)
-    n, ok := n.SetString(hexStr, 16)
-    if !ok {
-        return 0, fmt.Errorf("failed to parse hex string: %s", hexStr)
-    }
-    
-    // Convert big.Int to int64
-    if !n.IsInt64() {
-        return 0, fmt.Errorf("hex value too large for int64: %s", hexStr)
+}
+
+// combineWithEngineAPI wraps primary so it runs alongside engineAPIFeature and their
+// responses are merged into one, escalating to whichever reports the worse severity.
+// sdk's grpcServer.Execute loops over every registered callback and simply overwrites
+// the response with each one's return value in order, so registering both features
+// separately would mean engineAPIFeature's result silently wins on every poll.
+func combineWithEngineAPI(primary func(info, option map[string]*structpb.Value) (sdk.CallResponse, error)) func(map[string]*structpb.Value, map[string]*structpb.Value) (sdk.CallResponse, error) {
+    return func(info, option map[string]*structpb.Value) (sdk.CallResponse, error) {
+        primaryResp, _ := primary(info, option)
+        engineResp, _ := engineAPIFeature(info, option)
+
+        ret := primaryResp
+        ret.Message = fmt.Sprintf("%s | engine API: %s", primaryResp.Message, engineResp.Message)
+        if severityRank(engineResp.Severity) > severityRank(primaryResp.Severity) {
+            ret.Severity = engineResp.Severity
+            ret.State = engineResp.State
+        }
+        return ret, nil
     }
-    
-    return n.Int64(), nil
 }
@@ -0,0 +1,26 @@
+package main
+
+import (
+    "testing"
+
+    pluginpb "github.com/dsrvlabs/vatz-proto/plugin/v1"
+)
+
+func TestSeverityRank(t *testing.T) {
+    tests := []struct {
+        worse pluginpb.SEVERITY
+        other pluginpb.SEVERITY
+    }{
+        {pluginpb.SEVERITY_CRITICAL, pluginpb.SEVERITY_ERROR},
+        {pluginpb.SEVERITY_ERROR, pluginpb.SEVERITY_WARNING},
+        {pluginpb.SEVERITY_WARNING, pluginpb.SEVERITY_INFO},
+        {pluginpb.SEVERITY_INFO, pluginpb.SEVERITY_UNKNOWN},
+    }
+
+    for _, tc := range tests {
+        if severityRank(tc.worse) <= severityRank(tc.other) {
+            t.Errorf("severityRank(%v) = %d, want it to rank above severityRank(%v) = %d",
+                tc.worse, severityRank(tc.worse), tc.other, severityRank(tc.other))
+        }
+    }
+}
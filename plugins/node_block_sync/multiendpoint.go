@@ -0,0 +1,230 @@
+package main
+
+import (
+    "flag"
+    "fmt"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/ethclient"
+    "golang.org/x/net/context"
+
+    pluginpb "github.com/dsrvlabs/vatz-proto/plugin/v1"
+    "github.com/dsrvlabs/vatz/sdk"
+    "google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+    defaultMaxHeightDrift = 5
+    circuitBreakThreshold = 3
+    circuitBreakDuration  = 60 * time.Second
+)
+
+var (
+    maxHeightDrift int
+
+    endpoints     []*endpointState
+    endpointsOnce sync.Once
+
+    multiPrevHeight   int64
+    multiWarningCount int
+)
+
+func init() {
+    flag.IntVar(&maxHeightDrift, "max-height-drift", defaultMaxHeightDrift, "Maximum allowed block height spread across --rpc-url endpoints before raising a warning")
+}
+
+// stringListFlag implements flag.Value to let --rpc-url be repeated, or given a
+// comma-separated list, and accumulate into a single slice.
+type stringListFlag struct {
+    values *[]string
+}
+
+func (f *stringListFlag) String() string {
+    if f.values == nil {
+        return ""
+    }
+    return strings.Join(*f.values, ",")
+}
+
+func (f *stringListFlag) Set(v string) error {
+    for _, part := range strings.Split(v, ",") {
+        if part = strings.TrimSpace(part); part != "" {
+            *f.values = append(*f.values, part)
+        }
+    }
+    return nil
+}
+
+// endpointState tracks health and circuit-breaking for a single --rpc-url endpoint so
+// that a flaky provider can be skipped for a while instead of failing every tick.
+type endpointState struct {
+    URL string
+
+    mu                sync.Mutex
+    lastSuccess       time.Time
+    lastHeight        uint64
+    lastHash          common.Hash
+    consecutiveErrors int
+    circuitOpenUntil  time.Time
+}
+
+func (e *endpointState) circuitOpen() bool {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return time.Now().Before(e.circuitOpenUntil)
+}
+
+func (e *endpointState) recordSuccess(height uint64, hash common.Hash) {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    e.lastSuccess = time.Now()
+    e.lastHeight = height
+    e.lastHash = hash
+    e.consecutiveErrors = 0
+    e.circuitOpenUntil = time.Time{}
+}
+
+func (e *endpointState) recordFailure() {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    e.consecutiveErrors++
+    if e.consecutiveErrors >= circuitBreakThreshold {
+        e.circuitOpenUntil = time.Now().Add(circuitBreakDuration)
+    }
+}
+
+func initEndpoints() {
+    endpointsOnce.Do(func() {
+        for _, u := range rpcURLs {
+            endpoints = append(endpoints, &endpointState{URL: u})
+        }
+    })
+}
+
+// heightSpread returns the lowest and highest height reported across healthy, which
+// must be non-empty.
+func heightSpread(healthy []endpointResult) (min, max uint64) {
+    min, max = healthy[0].height, healthy[0].height
+    for _, r := range healthy[1:] {
+        if r.height < min {
+            min = r.height
+        }
+        if r.height > max {
+            max = r.height
+        }
+    }
+    return min, max
+}
+
+type endpointResult struct {
+    state  *endpointState
+    height uint64
+    hash   common.Hash
+    err    error
+}
+
+func pollEndpoint(ctx context.Context, e *endpointState) endpointResult {
+    if e.circuitOpen() {
+        return endpointResult{state: e, err: fmt.Errorf("circuit open after %d consecutive failures", circuitBreakThreshold)}
+    }
+
+    ctx, cancel := context.WithTimeout(ctx, defaultRPCTimeout)
+    defer cancel()
+
+    client, err := ethclient.DialContext(ctx, e.URL)
+    if err != nil {
+        e.recordFailure()
+        return endpointResult{state: e, err: err}
+    }
+    defer client.Close()
+
+    head, err := client.HeaderByNumber(ctx, nil)
+    if err != nil {
+        e.recordFailure()
+        return endpointResult{state: e, err: err}
+    }
+
+    height := head.Number.Uint64()
+    e.recordSuccess(height, head.Hash())
+    return endpointResult{state: e, height: height, hash: head.Hash()}
+}
+
+// multiEndpointFeature queries every configured --rpc-url endpoint in parallel, fails
+// over to the first healthy one for the reported height, and raises a warning when the
+// spread between endpoints exceeds --max-height-drift, which usually means one of them
+// is lagging or stuck on a minority fork.
+func multiEndpointFeature(info, option map[string]*structpb.Value) (sdk.CallResponse, error) {
+    ret := sdk.CallResponse{
+        FuncName: info["execute_method"].GetStringValue(),
+        Message:  "Unable to reach any configured RPC endpoint",
+        Severity: pluginpb.SEVERITY_UNKNOWN,
+        State:    pluginpb.STATE_NONE,
+    }
+
+    initEndpoints()
+
+    ctx := context.Background()
+    results := make([]endpointResult, len(endpoints))
+
+    var wg sync.WaitGroup
+    for i, e := range endpoints {
+        wg.Add(1)
+        go func(i int, e *endpointState) {
+            defer wg.Done()
+            results[i] = pollEndpoint(ctx, e)
+        }(i, e)
+    }
+    wg.Wait()
+
+    var healthy []endpointResult
+    breakdown := make([]string, 0, len(results))
+    for _, r := range results {
+        if r.err != nil {
+            breakdown = append(breakdown, fmt.Sprintf("%s: error (%v)", r.state.URL, r.err))
+            continue
+        }
+        healthy = append(healthy, r)
+        breakdown = append(breakdown, fmt.Sprintf("%s: %d", r.state.URL, r.height))
+    }
+
+    if len(healthy) == 0 {
+        ret.Severity = pluginpb.SEVERITY_CRITICAL
+        ret.State = pluginpb.STATE_FAILURE
+        err := fmt.Errorf("all %d RPC endpoints unreachable: %s", len(endpoints), strings.Join(breakdown, "; "))
+        ret.Message = err.Error()
+        return ret, err
+    }
+
+    primary := healthy[0]
+    minHeight, maxHeight := heightSpread(healthy)
+
+    if spread := maxHeight - minHeight; spread > uint64(maxHeightDrift) {
+        ret.Message = fmt.Sprintf("Endpoint height drift %d exceeds max %d: %s", spread, maxHeightDrift, strings.Join(breakdown, "; "))
+        ret.Severity = pluginpb.SEVERITY_WARNING
+        ret.State = pluginpb.STATE_SUCCESS
+        return ret, nil
+    }
+
+    latestHeight := primary.height
+    if int64(latestHeight) > multiPrevHeight {
+        ret.Message = fmt.Sprintf("Block height increasing. Current height: %d (%d/%d endpoints healthy)", latestHeight, len(healthy), len(endpoints))
+        ret.Severity = pluginpb.SEVERITY_INFO
+        multiWarningCount = 0
+    } else {
+        multiWarningCount++
+        if multiWarningCount > criticalCount {
+            ret.Message = fmt.Sprintf("Block height stuck more than %d times. Current height: %d", criticalCount, latestHeight)
+            ret.Severity = pluginpb.SEVERITY_CRITICAL
+        } else {
+            ret.Message = fmt.Sprintf("Block height stuck %d times. Current height: %d", multiWarningCount, latestHeight)
+            ret.Severity = pluginpb.SEVERITY_WARNING
+        }
+    }
+
+    ret.State = pluginpb.STATE_SUCCESS
+    multiPrevHeight = int64(latestHeight)
+    return ret, nil
+}
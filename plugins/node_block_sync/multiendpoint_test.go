@@ -0,0 +1,97 @@
+package main
+
+import (
+    "testing"
+
+    "github.com/ethereum/go-ethereum/common"
+)
+
+func TestStringListFlagSet(t *testing.T) {
+    var values []string
+    f := &stringListFlag{values: &values}
+
+    if err := f.Set("http://a, http://b"); err != nil {
+        t.Fatalf("Set returned error: %v", err)
+    }
+    if err := f.Set("http://c"); err != nil {
+        t.Fatalf("Set returned error: %v", err)
+    }
+
+    want := []string{"http://a", "http://b", "http://c"}
+    if len(values) != len(want) {
+        t.Fatalf("values = %v, want %v", values, want)
+    }
+    for i := range want {
+        if values[i] != want[i] {
+            t.Fatalf("values = %v, want %v", values, want)
+        }
+    }
+}
+
+func TestStringListFlagSetSkipsBlanks(t *testing.T) {
+    var values []string
+    f := &stringListFlag{values: &values}
+
+    if err := f.Set("http://a,, ,http://b"); err != nil {
+        t.Fatalf("Set returned error: %v", err)
+    }
+
+    if len(values) != 2 || values[0] != "http://a" || values[1] != "http://b" {
+        t.Fatalf("values = %v, want [http://a http://b]", values)
+    }
+}
+
+func TestEndpointStateCircuitBreaker(t *testing.T) {
+    e := &endpointState{URL: "http://node"}
+
+    for i := 0; i < circuitBreakThreshold-1; i++ {
+        e.recordFailure()
+        if e.circuitOpen() {
+            t.Fatalf("circuit opened after only %d failures, want it closed below threshold %d", i+1, circuitBreakThreshold)
+        }
+    }
+
+    e.recordFailure()
+    if !e.circuitOpen() {
+        t.Fatalf("circuit did not open after %d consecutive failures", circuitBreakThreshold)
+    }
+
+    e.recordSuccess(100, common.Hash{})
+    if e.circuitOpen() {
+        t.Fatal("circuit stayed open after a recorded success")
+    }
+    if e.consecutiveErrors != 0 {
+        t.Fatalf("consecutiveErrors = %d, want 0 after a recorded success", e.consecutiveErrors)
+    }
+}
+
+func TestHeightSpread(t *testing.T) {
+    tests := []struct {
+        name     string
+        healthy  []endpointResult
+        wantMin  uint64
+        wantMax  uint64
+    }{
+        {
+            name:    "single endpoint has zero spread",
+            healthy: []endpointResult{{height: 100}},
+            wantMin: 100,
+            wantMax: 100,
+        },
+        {
+            name:    "spread across lagging and leading endpoints",
+            healthy: []endpointResult{{height: 100}, {height: 95}, {height: 103}},
+            wantMin: 95,
+            wantMax: 103,
+        },
+    }
+
+    for _, tc := range tests {
+        t.Run(tc.name, func(t *testing.T) {
+            min, max := heightSpread(tc.healthy)
+            if min != tc.wantMin || max != tc.wantMax {
+                t.Fatalf("heightSpread = (%d, %d), want (%d, %d)", min, max, tc.wantMin, tc.wantMax)
+            }
+        })
+    }
+}
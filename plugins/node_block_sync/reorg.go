@@ -0,0 +1,104 @@
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "time"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/core/types"
+    "github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+    defaultReorgHistorySize   = 32
+    defaultReorgCriticalDepth = 6
+
+    // reorgWalkTimeout bounds detectReorg's ancestor walk, which can make up to
+    // defaultReorgHistorySize sequential HeaderByHash round-trips. It needs its own,
+    // longer budget than the single-header poll so a deep-but-legitimate reorg isn't
+    // mistaken for a timeout.
+    reorgWalkTimeout = 30 * time.Second
+)
+
+var (
+    reorgCriticalDepth int
+
+    // blockHistory tracks the last defaultReorgHistorySize canonical headers seen by
+    // pluginFeature, ascending by height, so a reorg that replaces the tip with a
+    // block at an equal or lower height can still be detected.
+    blockHistory []blockRef
+)
+
+type blockRef struct {
+    Height uint64
+    Hash   common.Hash
+}
+
+func init() {
+    flag.IntVar(&reorgCriticalDepth, "reorg-critical-depth", defaultReorgCriticalDepth, "Reorg depth (in blocks) at which to raise critical level of alert")
+}
+
+// detectReorg compares head against the last recorded tip and, if they don't link up
+// directly, walks head's ancestor chain looking for the height at which it reconnects
+// with the tracked history. depth is how many blocks back of the old tip were replaced;
+// detected is false when head simply extends the tracked history with no divergence.
+// The walk is given its own timeout budget, separate from ctx's caller-imposed
+// deadline, since it can take up to defaultReorgHistorySize round-trips.
+func detectReorg(ctx context.Context, client *ethclient.Client, head *types.Header) (depth int, detected bool, err error) {
+    if len(blockHistory) == 0 {
+        return 0, false, nil
+    }
+
+    lastTip := blockHistory[len(blockHistory)-1]
+    if head.Number.Uint64() == lastTip.Height+1 && head.ParentHash == lastTip.Hash {
+        return 0, false, nil
+    }
+
+    ctx, cancel := context.WithTimeout(ctx, reorgWalkTimeout)
+    defer cancel()
+
+    cur := head
+    for d := 0; d <= defaultReorgHistorySize; d++ {
+        if stored, ok := historyHashAt(cur.Number.Uint64()); ok && stored == cur.Hash() {
+            depth := int(lastTip.Height) - int(cur.Number.Uint64())
+            if depth <= 0 {
+                // head reconnects with the tracked history at or above the last
+                // recorded tip with no hash divergence anywhere along the path:
+                // either nothing has changed, or this is a multi-block catch-up,
+                // not a reorg.
+                return 0, false, nil
+            }
+            return depth, true, nil
+        }
+
+        if cur.Number.Sign() == 0 {
+            break
+        }
+
+        parent, perr := client.HeaderByHash(ctx, cur.ParentHash)
+        if perr != nil {
+            return int(lastTip.Height) + 1, true, fmt.Errorf("failed to walk back past height %d: %w", cur.Number.Uint64(), perr)
+        }
+        cur = parent
+    }
+
+    return int(lastTip.Height) + defaultReorgHistorySize, true, nil
+}
+
+func historyHashAt(height uint64) (common.Hash, bool) {
+    for _, b := range blockHistory {
+        if b.Height == height {
+            return b.Hash, true
+        }
+    }
+    return common.Hash{}, false
+}
+
+func recordBlock(height uint64, hash common.Hash) {
+    blockHistory = append(blockHistory, blockRef{Height: height, Hash: hash})
+    if len(blockHistory) > defaultReorgHistorySize {
+        blockHistory = blockHistory[len(blockHistory)-defaultReorgHistorySize:]
+    }
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+    "context"
+    "testing"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/core/types"
+    "math/big"
+)
+
+func header(number uint64, parentHash common.Hash, extra byte) *types.Header {
+    return &types.Header{
+        Number:     new(big.Int).SetUint64(number),
+        ParentHash: parentHash,
+        Extra:      []byte{extra},
+    }
+}
+
+func TestDetectReorg(t *testing.T) {
+    tip := header(100, common.Hash{0x64}, 1)
+
+    tests := []struct {
+        name         string
+        blockHistory []blockRef
+        head         *types.Header
+        wantDetected bool
+        wantDepth    int
+    }{
+        {
+            name:         "empty history never reports a reorg",
+            blockHistory: nil,
+            head:         tip,
+            wantDetected: false,
+        },
+        {
+            name:         "unchanged head at a stuck height is not a reorg",
+            blockHistory: []blockRef{{Height: 100, Hash: tip.Hash()}},
+            head:         tip,
+            wantDetected: false,
+        },
+        {
+            name:         "direct single-block extension is not a reorg",
+            blockHistory: []blockRef{{Height: 100, Hash: tip.Hash()}},
+            head:         header(101, tip.Hash(), 2),
+            wantDetected: false,
+        },
+    }
+
+    for _, tc := range tests {
+        t.Run(tc.name, func(t *testing.T) {
+            blockHistory = tc.blockHistory
+            depth, detected, err := detectReorg(context.Background(), nil, tc.head)
+            if err != nil {
+                t.Fatalf("detectReorg returned error: %v", err)
+            }
+            if detected != tc.wantDetected {
+                t.Fatalf("detected = %v, want %v", detected, tc.wantDetected)
+            }
+            if detected && depth != tc.wantDepth {
+                t.Fatalf("depth = %d, want %d", depth, tc.wantDepth)
+            }
+        })
+    }
+
+    blockHistory = nil
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/ethereum/go-ethereum/core/types"
+    "github.com/ethereum/go-ethereum/ethclient"
+    "github.com/rs/zerolog/log"
+
+    pluginpb "github.com/dsrvlabs/vatz-proto/plugin/v1"
+    "github.com/dsrvlabs/vatz/sdk"
+    "google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+    defaultHeadTimeout   = 30 * time.Second
+    maxSubscribeBackoff  = time.Minute
+)
+
+var (
+    rpcURL      string
+    rpcURLs     []string
+    headTimeout time.Duration
+
+    headMu     sync.Mutex
+    lastHeadAt time.Time
+)
+
+func init() {
+    flag.Var(&stringListFlag{values: &rpcURLs}, "rpc-url", "Execution client RPC endpoint; ws:// or wss:// enables a newHeads subscription instead of polling. May be repeated or comma-separated to enable multi-endpoint failover")
+    flag.DurationVar(&headTimeout, "head-timeout", defaultHeadTimeout, "How long to wait for a newHeads notification before escalating to warning (WebSocket mode only)")
+}
+
+// normalizeRPCURLs fills in the default endpoint when none were configured and sets
+// rpcURL to the first configured endpoint, which is what WebSocket subscription mode
+// and the single-endpoint polling path both act on.
+func normalizeRPCURLs() {
+    if len(rpcURLs) == 0 {
+        rpcURLs = []string{defaultPublicRPCURL}
+    }
+    rpcURL = rpcURLs[0]
+}
+
+func isWebSocketURL(url string) bool {
+    return strings.HasPrefix(url, "ws://") || strings.HasPrefix(url, "wss://")
+}
+
+// startHeadSubscription opens a persistent eth_subscribe("newHeads") subscription and
+// keeps prevHeight/warningCount/blockHistory updated as notifications arrive. It
+// reconnects with exponential backoff, since most providers drop idle WS connections
+// after a few minutes.
+func startHeadSubscription(ctx context.Context) {
+    go runHeadSubscription(ctx)
+}
+
+func runHeadSubscription(ctx context.Context) {
+    backoff := time.Second
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        default:
+        }
+
+        client, err := ethclient.DialContext(ctx, rpcURL)
+        if err != nil {
+            log.Error().Str("module", "subscription").Msgf("failed to dial %s: %v", rpcURL, err)
+            backoff = sleepBackoff(ctx, backoff)
+            continue
+        }
+
+        headCh := make(chan *types.Header)
+        sub, err := client.SubscribeNewHead(ctx, headCh)
+        if err != nil {
+            log.Error().Str("module", "subscription").Msgf("failed to subscribe to newHeads: %v", err)
+            client.Close()
+            backoff = sleepBackoff(ctx, backoff)
+            continue
+        }
+
+        log.Info().Str("module", "subscription").Msgf("subscribed to newHeads on %s", rpcURL)
+        backoff = time.Second
+
+    subscribed:
+        for {
+            select {
+            case <-ctx.Done():
+                sub.Unsubscribe()
+                client.Close()
+                return
+            case err := <-sub.Err():
+                log.Error().Str("module", "subscription").Msgf("newHeads subscription dropped: %v", err)
+                client.Close()
+                break subscribed
+            case head := <-headCh:
+                onNewHead(ctx, client, head)
+            }
+        }
+
+        backoff = sleepBackoff(ctx, backoff)
+    }
+}
+
+func sleepBackoff(ctx context.Context, backoff time.Duration) time.Duration {
+    select {
+    case <-ctx.Done():
+    case <-time.After(backoff):
+    }
+
+    next := backoff * 2
+    if next > maxSubscribeBackoff {
+        next = maxSubscribeBackoff
+    }
+    return next
+}
+
+func onNewHead(ctx context.Context, client *ethclient.Client, head *types.Header) {
+    headMu.Lock()
+    defer headMu.Unlock()
+
+    latestHeight := head.Number.Uint64()
+
+    if depth, detected, err := detectReorg(ctx, client, head); err != nil {
+        log.Error().Str("module", "subscription").Msgf("failed to check for reorg: %v", err)
+    } else if detected {
+        if depth > reorgCriticalDepth {
+            log.Warn().Str("module", "subscription").Msgf("deep reorg detected at depth %d (current height: %d)", depth, latestHeight)
+        } else {
+            log.Warn().Str("module", "subscription").Msgf("reorg detected at depth %d (current height: %d)", depth, latestHeight)
+        }
+    }
+
+    recordBlock(latestHeight, head.Hash())
+
+    if int64(latestHeight) > prevHeight {
+        warningCount = 0
+    } else {
+        warningCount++
+    }
+
+    prevHeight = int64(latestHeight)
+    lastHeadAt = time.Now()
+}
+
+// subscriptionFeature reports the state accumulated by the newHeads subscription
+// goroutine instead of polling itself, escalating severity the longer no head has
+// arrived: one head-timeout interval past due is a warning, each doubling beyond that
+// raises it to critical.
+func subscriptionFeature(info, option map[string]*structpb.Value) (sdk.CallResponse, error) {
+    ret := sdk.CallResponse{
+        FuncName: info["execute_method"].GetStringValue(),
+        Message:  "No newHeads notification received yet",
+        Severity: pluginpb.SEVERITY_WARNING,
+        State:    pluginpb.STATE_FAILURE,
+    }
+
+    headMu.Lock()
+    height := prevHeight
+    warnings := warningCount
+    last := lastHeadAt
+    headMu.Unlock()
+
+    if last.IsZero() {
+        return ret, nil
+    }
+
+    if staleness := time.Since(last); staleness > headTimeout {
+        severity := pluginpb.SEVERITY_WARNING
+        for threshold := headTimeout * 2; staleness > threshold; threshold *= 2 {
+            severity = pluginpb.SEVERITY_CRITICAL
+        }
+
+        ret.Message = fmt.Sprintf("No newHeads notification for %s (last height: %d)", staleness.Round(time.Second), height)
+        ret.Severity = severity
+        ret.State = pluginpb.STATE_FAILURE
+        return ret, nil
+    }
+
+    switch {
+    case warnings > criticalCount:
+        ret.Message = fmt.Sprintf("Block height stuck more than %d times. Current height: %d", criticalCount, height)
+        ret.Severity = pluginpb.SEVERITY_CRITICAL
+    case warnings > 0:
+        ret.Message = fmt.Sprintf("Block height stuck %d times. Current height: %d", warnings, height)
+        ret.Severity = pluginpb.SEVERITY_WARNING
+    default:
+        ret.Message = fmt.Sprintf("Block height increasing. Current height: %d", height)
+        ret.Severity = pluginpb.SEVERITY_INFO
+    }
+
+    ret.State = pluginpb.STATE_SUCCESS
+    return ret, nil
+}
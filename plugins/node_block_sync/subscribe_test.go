@@ -0,0 +1,76 @@
+package main
+
+import (
+    "testing"
+    "time"
+
+    pluginpb "github.com/dsrvlabs/vatz-proto/plugin/v1"
+)
+
+func TestIsWebSocketURL(t *testing.T) {
+    tests := []struct {
+        url  string
+        want bool
+    }{
+        {"ws://localhost:8546", true},
+        {"wss://mainnet.example.com", true},
+        {"http://localhost:8545", false},
+        {"https://mainnet.example.com", false},
+        {"", false},
+    }
+
+    for _, tc := range tests {
+        if got := isWebSocketURL(tc.url); got != tc.want {
+            t.Errorf("isWebSocketURL(%q) = %v, want %v", tc.url, got, tc.want)
+        }
+    }
+}
+
+func TestSubscriptionFeatureStaleness(t *testing.T) {
+    origHeadTimeout, origLastHeadAt, origPrevHeight, origWarningCount := headTimeout, lastHeadAt, prevHeight, warningCount
+    defer func() {
+        headTimeout, lastHeadAt, prevHeight, warningCount = origHeadTimeout, origLastHeadAt, origPrevHeight, origWarningCount
+    }()
+
+    headTimeout = time.Second
+    prevHeight = 42
+    warningCount = 0
+
+    tests := []struct {
+        name         string
+        staleness    time.Duration
+        wantSeverity pluginpb.SEVERITY
+    }{
+        {"just past the timeout is a warning", headTimeout + 100*time.Millisecond, pluginpb.SEVERITY_WARNING},
+        {"past double the timeout is critical", headTimeout*2 + 100*time.Millisecond, pluginpb.SEVERITY_CRITICAL},
+    }
+
+    for _, tc := range tests {
+        t.Run(tc.name, func(t *testing.T) {
+            lastHeadAt = time.Now().Add(-tc.staleness)
+
+            resp, err := subscriptionFeature(nil, nil)
+            if err != nil {
+                t.Fatalf("subscriptionFeature returned error: %v", err)
+            }
+            if resp.Severity != tc.wantSeverity {
+                t.Fatalf("Severity = %v, want %v", resp.Severity, tc.wantSeverity)
+            }
+        })
+    }
+}
+
+func TestSubscriptionFeatureNoHeadYet(t *testing.T) {
+    origLastHeadAt := lastHeadAt
+    defer func() { lastHeadAt = origLastHeadAt }()
+
+    lastHeadAt = time.Time{}
+
+    resp, err := subscriptionFeature(nil, nil)
+    if err != nil {
+        t.Fatalf("subscriptionFeature returned error: %v", err)
+    }
+    if resp.State != pluginpb.STATE_FAILURE {
+        t.Fatalf("State = %v, want STATE_FAILURE before any newHeads notification has arrived", resp.State)
+    }
+}